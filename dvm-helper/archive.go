@@ -0,0 +1,106 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// archiveFileExt returns the extension of the release archive Docker
+// publishes for the current platform: a zip on Windows, a gzipped tarball
+// everywhere else.
+func archiveFileExt() string {
+	if dockerOS == "windows" {
+		return ".zip"
+	}
+	return ".tgz"
+}
+
+// archiveBinaryPath is the path of the docker client binary inside the
+// release archive, e.g. "docker/docker" or "docker/docker.exe".
+func archiveBinaryPath() string {
+	return path.Join("docker", getBinaryName())
+}
+
+func extractFileFromArchive(archivePath string, innerPath string, destPath string) error {
+	if strings.HasSuffix(archivePath, ".zip") {
+		return extractFileFromZip(archivePath, innerPath, destPath)
+	}
+	return extractFileFromTarball(archivePath, innerPath, destPath)
+}
+
+func extractFileFromTarball(archivePath string, innerPath string, destPath string) error {
+	archiveFile, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer archiveFile.Close()
+
+	gzipReader, err := gzip.NewReader(archiveFile)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s not found in %s", innerPath, archivePath)
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Name != innerPath {
+			continue
+		}
+
+		return writeExtractedFile(destPath, tarReader, os.FileMode(header.Mode))
+	}
+}
+
+func extractFileFromZip(archivePath string, innerPath string, destPath string) error {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zipReader.Close()
+
+	for _, file := range zipReader.File {
+		if file.Name != innerPath {
+			continue
+		}
+
+		fileReader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer fileReader.Close()
+
+		return writeExtractedFile(destPath, fileReader, file.Mode())
+	}
+
+	return fmt.Errorf("%s not found in %s", innerPath, archivePath)
+}
+
+func writeExtractedFile(destPath string, src io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+
+	destFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+
+	_, err = io.Copy(destFile, src)
+	return err
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getcarina/dvm/dvm-helper/dockerversion"
+)
+
+func TestBuildDownloadURL_ArchivedRelease(t *testing.T) {
+	originalOS, originalArch := dockerOS, dockerArch
+	defer func() { dockerOS, dockerArch = originalOS, originalArch }()
+
+	testCases := []struct {
+		os       string
+		arch     string
+		expected string
+	}{
+		{"linux", "x86_64", "https://download.docker.com/linux/static/stable/x86_64/docker-1.12.3.tgz"},
+		{"mac", "x86_64", "https://download.docker.com/mac/static/stable/x86_64/docker-1.12.3.tgz"},
+		{"windows", "x86_64", "https://download.docker.com/windows/static/stable/x86_64/docker-1.12.3.zip"},
+	}
+
+	for _, testCase := range testCases {
+		dockerOS, dockerArch = testCase.os, testCase.arch
+		actual := buildDownloadURL(dockerversion.New("1.12.3"))
+		if actual != testCase.expected {
+			t.Errorf("%s/%s: expected %s, got %s", testCase.os, testCase.arch, testCase.expected, actual)
+		}
+	}
+}
+
+func TestBuildDownloadURL_LegacyRelease(t *testing.T) {
+	originalOS, originalArch := dockerOS, dockerArch
+	defer func() { dockerOS, dockerArch = originalOS, originalArch }()
+
+	dockerOS, dockerArch = "linux", "x86_64"
+	actual := buildDownloadURL(dockerversion.New("1.9.1"))
+	expected := "https://get.docker.com/builds/linux/x86_64/docker-1.9.1"
+	if actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestBuildDownloadURL_Experimental(t *testing.T) {
+	originalOS, originalArch := dockerOS, dockerArch
+	defer func() { dockerOS, dockerArch = originalOS, originalArch }()
+
+	dockerOS, dockerArch = "linux", "x86_64"
+	actual := buildDownloadURL(dockerversion.New(dockerversion.Experimental))
+	expected := "https://experimental.docker.com/builds/linux/x86_64/docker-latest"
+	if actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestExtractFileFromTarball(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "dvm-archive-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	archivePath := filepath.Join(tempDir, "docker-1.12.3.tgz")
+	writeTestTarball(t, archivePath, "docker/docker", "fake docker binary")
+
+	destPath := filepath.Join(tempDir, "extracted", "docker")
+	if err := extractFileFromTarball(archivePath, "docker/docker", destPath); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExtractedContents(t, destPath, "fake docker binary")
+}
+
+func TestExtractFileFromZip(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "dvm-archive-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	archivePath := filepath.Join(tempDir, "docker-1.12.3.zip")
+	writeTestZip(t, archivePath, "docker/docker.exe", "fake docker binary")
+
+	destPath := filepath.Join(tempDir, "extracted", "docker.exe")
+	if err := extractFileFromZip(archivePath, "docker/docker.exe", destPath); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExtractedContents(t, destPath, "fake docker binary")
+}
+
+func writeTestTarball(t *testing.T, archivePath string, innerPath string, contents string) {
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archiveFile.Close()
+
+	gzipWriter := gzip.NewWriter(archiveFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	header := &tar.Header{Name: innerPath, Mode: 0755, Size: int64(len(contents))}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tarWriter.Write([]byte(contents)); err != nil {
+		t.Fatal(err)
+	}
+
+	tarWriter.Close()
+	gzipWriter.Close()
+}
+
+func writeTestZip(t *testing.T, archivePath string, innerPath string, contents string) {
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archiveFile.Close()
+
+	zipWriter := zip.NewWriter(archiveFile)
+	fileWriter, err := zipWriter.Create(innerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fileWriter.Write([]byte(contents)); err != nil {
+		t.Fatal(err)
+	}
+
+	zipWriter.Close()
+}
+
+func assertExtractedContents(t *testing.T, path string, expected string) {
+	actual, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(actual, []byte(expected)) {
+		t.Errorf("expected extracted contents %q, got %q", expected, actual)
+	}
+}
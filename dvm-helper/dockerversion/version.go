@@ -0,0 +1,116 @@
+// Package dockerversion represents the Docker client versions that dvm can
+// install and activate, including the special "experimental" build and
+// semver prereleases, in a single comparable type.
+package dockerversion
+
+import (
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// Experimental is the version string dvm treats as the rolling experimental
+// Docker build, rather than an exact, semver-comparable release.
+const Experimental = "experimental"
+
+// firstArchivedRelease is the earliest Docker version published by Docker as
+// a tarball/zip archive rather than a single static binary.
+var firstArchivedRelease = semver.MustParse("1.11.0")
+
+// Version identifies a Docker release that dvm can install or activate. It
+// may be an exact, semver-comparable release (stable or prerelease), the
+// rolling Experimental build, or an alias that resolved to one of those.
+type Version struct {
+	// Raw is the version exactly as dvm resolved it, e.g. "1.12.3",
+	// "1.12.0-rc1", or "experimental".
+	Raw string
+	// SemVer is the parsed semantic version. It is the zero value when Raw
+	// is Experimental or otherwise did not parse as a semantic version.
+	SemVer semver.Version
+	// Alias is the alias name this Version was resolved from, if any.
+	Alias string
+
+	isSemVer bool
+}
+
+// New parses raw, an exact Docker version such as "1.12.3" or "experimental",
+// into a Version.
+func New(raw string) Version {
+	return newVersion(raw, "")
+}
+
+// NewFromAlias parses raw the same way as New, additionally recording the
+// alias name it was resolved from.
+func NewFromAlias(alias string, raw string) Version {
+	return newVersion(raw, alias)
+}
+
+func newVersion(raw string, alias string) Version {
+	v := Version{Raw: raw, Alias: alias}
+	if raw == Experimental {
+		return v
+	}
+
+	semVer, err := semver.Parse(strings.TrimPrefix(raw, "v"))
+	if err == nil {
+		v.SemVer = semVer
+		v.isSemVer = true
+	}
+	return v
+}
+
+// String returns the version as it should be displayed to users and used to
+// build file paths and download URLs.
+func (v Version) String() string {
+	return v.Raw
+}
+
+// Slug returns a filesystem and URL safe identifier for this version.
+func (v Version) Slug() string {
+	return v.Raw
+}
+
+// IsExperimental reports whether this is the rolling experimental build.
+func (v Version) IsExperimental() bool {
+	return v.Raw == Experimental
+}
+
+// IsSemVer reports whether Raw parsed as a semantic version, as opposed to
+// being an alias or the experimental build.
+func (v Version) IsSemVer() bool {
+	return v.isSemVer
+}
+
+// IsPrerelease reports whether this version is a semver prerelease, e.g. an
+// RC or beta build such as "1.12.0-rc1".
+func (v Version) IsPrerelease() bool {
+	return v.isSemVer && len(v.SemVer.Pre) > 0
+}
+
+// ShouldUseArchivedRelease reports whether this version is published as a
+// tarball/zip archive (Docker >= 1.11) rather than as a single static
+// binary. Experimental is still published as a single static binary at its
+// own legacy URL, so it is never archived.
+func (v Version) ShouldUseArchivedRelease() bool {
+	if v.IsExperimental() {
+		return false
+	}
+	return v.isSemVer && v.SemVer.GE(firstArchivedRelease)
+}
+
+// Compare compares two Versions by semantic version, returning -1, 0 or 1.
+// The Experimental build always sorts after every semver release.
+func (v Version) Compare(other Version) int {
+	if v.IsExperimental() || other.IsExperimental() {
+		switch {
+		case v.IsExperimental() && other.IsExperimental():
+			return 0
+		case v.IsExperimental():
+			return 1
+		default:
+			return -1
+		}
+	}
+
+	return v.SemVer.Compare(other.SemVer)
+}
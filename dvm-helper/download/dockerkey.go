@@ -0,0 +1,16 @@
+package download
+
+// DockerReleasePublicKeyFingerprint identifies the GPG key Docker signs its
+// static release binaries with: https://docs.docker.com/engine/security/trust/
+const DockerReleasePublicKeyFingerprint = "9DC8 5822 9FC7 DD38 854A E2D8 8D81 803C 0EBF CD88"
+
+// DockerReleasePublicKey is the ASCII-armored public key matching
+// DockerReleasePublicKeyFingerprint, used to verify the detached ".asc"
+// signature on a downloaded release.
+//
+// This needs to be populated from Docker's published key before
+// --verify-signature can be used, e.g.:
+//
+//	gpg --keyserver hkps://keys.openpgp.org --recv-keys 0EBFCD88
+//	gpg --armor --export 0EBFCD88
+const DockerReleasePublicKey = ``
@@ -0,0 +1,207 @@
+// Package download fetches a Docker release binary or archive, optionally
+// verifying it against the checksum and signature Docker publishes
+// alongside it.
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/sync/errgroup"
+)
+
+// Options controls how File verifies and reports on a download.
+type Options struct {
+	// VerifyChecksum downloads url+".sha256" and verifies the downloaded
+	// file against it.
+	VerifyChecksum bool
+	// VerifySignature downloads url+".asc" and verifies it against
+	// PublicKey. PublicKey must be set when this is true.
+	VerifySignature bool
+	// PublicKey is the ASCII-armored public key used to verify the
+	// signature downloaded when VerifySignature is set.
+	PublicKey string
+	// Quiet suppresses the progress output normally written to stderr.
+	Quiet bool
+}
+
+// File downloads url to destPath, resuming a partial download left behind
+// by a previous attempt, and verifying it per opts before moving it into
+// place. The binary, its checksum and its signature are downloaded in
+// parallel.
+func File(url string, destPath string, opts Options) error {
+	if opts.VerifySignature && opts.PublicKey == "" {
+		return errors.New("cannot verify the release signature: no public key was provided")
+	}
+
+	tempPath := destPath + ".download"
+
+	var checksum, signature string
+	group := new(errgroup.Group)
+
+	group.Go(func() error {
+		return downloadToFile(url, tempPath, opts.Quiet)
+	})
+
+	if opts.VerifyChecksum {
+		group.Go(func() error {
+			body, err := fetchString(url + ".sha256")
+			checksum = body
+			return err
+		})
+	}
+
+	if opts.VerifySignature {
+		group.Go(func() error {
+			body, err := fetchString(url + ".asc")
+			signature = body
+			return err
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	if opts.VerifyChecksum {
+		if err := verifyChecksum(tempPath, checksum); err != nil {
+			os.Remove(tempPath)
+			return err
+		}
+	}
+
+	if opts.VerifySignature {
+		if err := verifySignature(tempPath, signature, opts.PublicKey); err != nil {
+			os.Remove(tempPath)
+			return err
+		}
+	}
+
+	return os.Rename(tempPath, destPath)
+}
+
+// fetchString retrieves the entire contents of url as a string.
+func fetchString(url string) (string, error) {
+	response, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to retrieve %s (status %d)", url, response.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	return string(body), err
+}
+
+// downloadToFile downloads url to destPath, resuming from destPath's
+// current size via an HTTP Range request when destPath already exists.
+func downloadToFile(url string, destPath string, quiet bool) error {
+	var resumeFrom int64
+	if info, err := os.Stat(destPath); err == nil {
+		resumeFrom = info.Size()
+	}
+
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if resumeFrom > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	openFlags := os.O_CREATE | os.O_WRONLY
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		openFlags |= os.O_APPEND
+	case http.StatusOK:
+		resumeFrom = 0
+		openFlags |= os.O_TRUNC
+	default:
+		return fmt.Errorf("unable to download %s (status %d)", url, response.StatusCode)
+	}
+
+	destFile, err := os.OpenFile(destPath, openFlags, 0644)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+
+	var writer io.Writer = destFile
+	if !quiet {
+		total := resumeFrom + response.ContentLength
+		progress := newProgressWriter(filepath.Base(url), resumeFrom, total)
+		defer progress.finish()
+		writer = io.MultiWriter(destFile, progress)
+	}
+
+	_, err = io.Copy(writer, response.Body)
+	return err
+}
+
+// verifyChecksum compares path's sha256 sum against sidecarContents, the
+// contents of the ".sha256" file Docker publishes alongside a release,
+// e.g. "abc123  docker-17.03.1-ce.tgz".
+func verifyChecksum(path string, sidecarContents string) error {
+	fields := strings.Fields(sidecarContents)
+	if len(fields) == 0 {
+		return errors.New("the checksum file is empty")
+	}
+	expectedSum := fields[0]
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return err
+	}
+
+	actualSum := hex.EncodeToString(hasher.Sum(nil))
+	if !strings.EqualFold(actualSum, expectedSum) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedSum, actualSum)
+	}
+	return nil
+}
+
+// verifySignature checks the detached, ASCII-armored signature against
+// path, using armoredPublicKey as the trusted keyring.
+func verifySignature(path string, armoredSignature string, armoredPublicKey string) error {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredPublicKey))
+	if err != nil {
+		return fmt.Errorf("unable to read the release public key: %s", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = openpgp.CheckArmoredDetachedSignature(keyring, file, strings.NewReader(armoredSignature))
+	if err != nil {
+		return fmt.Errorf("signature verification failed: %s", err)
+	}
+	return nil
+}
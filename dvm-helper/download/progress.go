@@ -0,0 +1,41 @@
+package download
+
+import (
+	"fmt"
+	"os"
+)
+
+// progressWriter writes a simple "<label>: NN%" progress indicator to
+// stderr as bytes are written to it, redrawing the line in place.
+type progressWriter struct {
+	label      string
+	downloaded int64
+	total      int64
+	lastPct    int64
+}
+
+func newProgressWriter(label string, downloaded int64, total int64) *progressWriter {
+	return &progressWriter{label: label, downloaded: downloaded, total: total, lastPct: -1}
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.downloaded += int64(len(p))
+
+	if w.total > 0 {
+		pct := w.downloaded * 100 / w.total
+		if pct != w.lastPct {
+			fmt.Fprintf(os.Stderr, "\r%s: %d%%", w.label, pct)
+			w.lastPct = pct
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "\r%s: %d bytes", w.label, w.downloaded)
+	}
+
+	return len(p), nil
+}
+
+// finish ends the progress line with a newline once the download is
+// complete, leaving the last progress value on screen.
+func (w *progressWriter) finish() {
+	fmt.Fprint(os.Stderr, "\r\n")
+}
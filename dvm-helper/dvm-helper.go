@@ -9,9 +9,13 @@ import "path/filepath"
 import "regexp"
 import "sort"
 import "strings"
+import "time"
 import "github.com/blang/semver"
 import "github.com/fatih/color"
+import "github.com/getcarina/dvm/dvm-helper/dockerversion"
+import "github.com/getcarina/dvm/dvm-helper/download"
 import "github.com/getcarina/dvm/dvm-helper/url"
+import "github.com/getcarina/dvm/dvm-helper/versionsource"
 import "github.com/google/go-github/github"
 import "github.com/codegangsta/cli"
 import "github.com/kardianos/osext"
@@ -24,6 +28,16 @@ var dvmDir string
 var debug bool
 var silent bool
 var token string
+var refreshVersionCache bool
+var includePrereleases bool
+var verifyChecksum = true
+
+// verifySignature defaults to false because download.DockerReleasePublicKey
+// is not yet populated with Docker's real release key (see dockerkey.go);
+// flip this default to true, and the --verify-signature flag below back to
+// an opt-out, once it is.
+var verifySignature = false
+var mirrorURL string
 
 // These are set during the build
 var dvmVersion string
@@ -52,10 +66,21 @@ func main() {
 		{
 			Name:    "install",
 			Aliases: []string{"i"},
-			Usage:   "dvm install [<version>], dvm install experimental\n\tInstall a Docker version, using $DOCKER_VERSION if the version is not specified.",
+			Usage:   "dvm install [<version>], dvm install experimental\n\tInstall a Docker version. <version> may be an exact version, a semver range (e.g. ^1.12.0), or latest/latest-stable/latest-lts/experimental. Uses $DOCKER_VERSION if not specified.",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "use-dvmrc", Usage: "When the version is not specified, read it from the nearest .dvmrc file before falling back to $DOCKER_VERSION."},
+				cli.BoolFlag{Name: "include-prereleases", Usage: "Allow version ranges and latest/latest-stable/latest-lts to resolve to a prerelease (RC/beta) build."},
+				cli.BoolFlag{Name: "no-verify-checksum", Usage: "Skip verifying the downloaded release against Docker's published checksum."},
+				cli.BoolFlag{Name: "verify-signature", Usage: "Verify the downloaded release against Docker's published GPG signature. Requires download.DockerReleasePublicKey to be populated."},
+				cli.StringFlag{Name: "mirror", EnvVar: "DVM_MIRROR", Usage: "Download Docker releases from this mirror instead of Docker's servers, e.g. for an air-gapped environment."},
+			},
 			Action: func(c *cli.Context) {
 				setGlobalVars(c)
-				install(c.Args().First())
+				includePrereleases = c.Bool("include-prereleases")
+				verifyChecksum = !c.Bool("no-verify-checksum")
+				verifySignature = c.Bool("verify-signature")
+				mirrorURL = c.String("mirror")
+				install(resolveVersionArg(c))
 			},
 		},
 		{
@@ -68,10 +93,23 @@ func main() {
 		},
 		{
 			Name:  "use",
-			Usage: "dvm use [<version>], dvm use system, dvm use experimental\n\tUse a Docker version, using $DOCKER_VERSION if the version is not specified.",
+			Usage: "dvm use [<version>], dvm use system, dvm use experimental\n\tUse a Docker version. <version> may be an exact version, a semver range (e.g. ^1.12.0), or latest/latest-stable/latest-lts/experimental. Uses $DOCKER_VERSION if not specified.",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "use-dvmrc", Usage: "When the version is not specified, read it from the nearest .dvmrc file before falling back to $DOCKER_VERSION."},
+				cli.BoolFlag{Name: "include-prereleases", Usage: "Allow version ranges and latest/latest-stable/latest-lts to resolve to a prerelease (RC/beta) build."},
+			},
 			Action: func(c *cli.Context) {
 				setGlobalVars(c)
-				use(c.Args().First())
+				includePrereleases = c.Bool("include-prereleases")
+				use(resolveVersionArg(c))
+			},
+		},
+		{
+			Name:  "auto",
+			Usage: "dvm auto\n\tUse the Docker version found in the nearest .dvmrc file.",
+			Action: func(c *cli.Context) {
+				setGlobalVars(c)
+				auto()
 			},
 		},
 		{
@@ -93,9 +131,12 @@ func main() {
 		{
 			Name:  "which",
 			Usage: "dvm which\n\tPrint the path to the current Docker version.",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "dvmrc", Usage: "Print the path to the Docker version selected by the nearest .dvmrc file instead of the active version."},
+			},
 			Action: func(c *cli.Context) {
 				setGlobalVars(c)
-				which()
+				which(c.Bool("dvmrc"))
 			},
 		},
 		{
@@ -127,8 +168,12 @@ func main() {
 			Name:    "list-remote",
 			Aliases: []string{"ls-remote"},
 			Usage:   "dvm list-remote [<pattern>]\n\tList available Docker versions.",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "refresh", Usage: "Bypass the local cache of available versions and fetch fresh results."},
+			},
 			Action: func(c *cli.Context) {
 				setGlobalVars(c)
+				refreshVersionCache = c.Bool("refresh")
 				listRemote(c.Args().First())
 			},
 		},
@@ -153,6 +198,32 @@ func main() {
 				upgrade(c.Bool("check"), c.String("version"))
 			},
 		},
+		{
+			Name:  "hook",
+			Usage: "dvm hook bash|zsh|powershell\n\tPrint a shell snippet that automatically runs `dvm auto` on cd, for the calling wrapper to eval.",
+			Action: func(c *cli.Context) {
+				setGlobalVars(c)
+				hook(c.Args().First())
+			},
+		},
+		{
+			Name:            "exec",
+			Usage:           "dvm exec <version> -- <command> [<args...>]\n\tRun a command with <version>'s Docker client directory prepended to PATH, without activating it in the current shell.",
+			SkipFlagParsing: true,
+			Action: func(c *cli.Context) {
+				setGlobalVars(c)
+				execCommand(c.Args())
+			},
+		},
+		{
+			Name:            "run",
+			Usage:           "dvm run <version> -- docker <args...>\n\tRun <args...> with <version>'s docker binary directly.",
+			SkipFlagParsing: true,
+			Action: func(c *cli.Context) {
+				setGlobalVars(c)
+				runDockerCommand(c.Args())
+			},
+		},
 	}
 
 	app.Run(os.Args)
@@ -206,6 +277,190 @@ func buildDvmReleaseURL(version string, elem ...string) string {
 	return url.Join(prefix, suffix)
 }
 
+// resolveVersionArg returns the version passed positionally on the command
+// line, or, when --use-dvmrc was passed and no version was given, the
+// version read from the nearest .dvmrc file.
+func resolveVersionArg(c *cli.Context) string {
+	version := c.Args().First()
+	if version != "" || !c.Bool("use-dvmrc") {
+		return version
+	}
+
+	dvmrcVersion, err := readDvmrc()
+	if err != nil {
+		writeDebug("Not using .dvmrc: %s", err)
+		return ""
+	}
+
+	writeDebug("Using version from .dvmrc: %s", dvmrcVersion)
+	return dvmrcVersion
+}
+
+func auto() {
+	version, err := readDvmrc()
+	if err != nil {
+		die("Unable to read a version from a .dvmrc file in the current directory or any parent directory.", err, retCodeInvalidOperation)
+	}
+
+	use(version)
+}
+
+// findDvmrcPath walks from the current working directory up to the
+// filesystem root looking for a .dvmrc file.
+func findDvmrcPath() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		candidatePath := filepath.Join(dir, ".dvmrc")
+		if pathExists(candidatePath) {
+			return candidatePath, nil
+		}
+
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			return "", errors.New("No .dvmrc file found")
+		}
+		dir = parentDir
+	}
+}
+
+// readDvmrc returns the version or alias recorded in the nearest .dvmrc file.
+func readDvmrc() (string, error) {
+	dvmrcPath, err := findDvmrcPath()
+	if err != nil {
+		return "", err
+	}
+
+	contents, err := ioutil.ReadFile(dvmrcPath)
+	if err != nil {
+		return "", err
+	}
+
+	version := strings.TrimSpace(string(contents))
+	if version == "" {
+		return "", fmt.Errorf("%s is empty", dvmrcPath)
+	}
+
+	return version, nil
+}
+
+func hook(shellName string) {
+	switch shellName {
+	case "bash":
+		writeInfo(bashHookScript)
+	case "zsh":
+		writeInfo(zshHookScript)
+	case "powershell":
+		writeInfo(powershellHookScript)
+	default:
+		die("dvm hook requires a shell name of bash, zsh or powershell.", nil, retCodeInvalidArgument)
+	}
+}
+
+const bashHookScript = `_dvm_hook() {
+  trap -- '' SIGINT
+  dvm auto >/dev/null 2>&1
+  trap - SIGINT
+}
+if [[ ";${PROMPT_COMMAND[*]:-};" != *";_dvm_hook;"* ]]; then
+  PROMPT_COMMAND="_dvm_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi`
+
+const zshHookScript = `_dvm_hook() {
+  trap -- '' SIGINT
+  dvm auto >/dev/null 2>&1
+  trap - SIGINT
+}
+typeset -ag chpwd_functions
+if [[ -z "${chpwd_functions[(r)_dvm_hook]+1}" ]]; then
+  chpwd_functions=(_dvm_hook $chpwd_functions)
+fi
+_dvm_hook`
+
+const powershellHookScript = `function global:Set-DvmAuto {
+  if (Test-Path .dvmrc -PathType Leaf) {
+    dvm auto
+  }
+}
+if (-not (Test-Path Function:\prompt_orig_dvm)) {
+  Rename-Item Function:\prompt Function:\prompt_orig_dvm
+  function global:prompt {
+    Set-DvmAuto
+    prompt_orig_dvm
+  }
+}`
+
+// execCommand implements `dvm exec <version> -- <command> [<args...>]`: it
+// resolves version via resolveUseVersion -- which falls back to resolving
+// against the versions available to download when no installed version
+// satisfies it, so a range or symbolic selector works on a fresh machine --
+// installs it if needed, then runs command with PATH prefixed by that
+// version's directory, leaving the current shell untouched.
+func execCommand(args cli.Args) {
+	if len(args) == 0 {
+		die("The exec command requires a version and a command to run, e.g. `dvm exec 1.12.3 -- docker ps`.", nil, retCodeInvalidArgument)
+	}
+
+	version := resolveUseVersion(args[0])
+	ensureVersionDownloaded(version)
+
+	commandArgs := stripDoubleDashSeparator(args[1:])
+	if len(commandArgs) == 0 {
+		die("The exec command requires a command to run after the version, e.g. `dvm exec 1.12.3 -- docker ps`.", nil, retCodeInvalidArgument)
+	}
+
+	env := append(os.Environ(), "PATH="+getVersionDir(version)+string(os.PathListSeparator)+os.Getenv("PATH"))
+	execProcess(commandArgs[0], commandArgs[1:], env)
+}
+
+// runDockerCommand implements `dvm run <version> -- docker <args...>`: it
+// resolves version the same way execCommand does -- falling back to the
+// versions available to download when nothing installed satisfies it --
+// installs it if needed, then shells out directly to that version's docker
+// binary.
+func runDockerCommand(args cli.Args) {
+	if len(args) == 0 {
+		die("The run command requires a version, e.g. `dvm run 1.12.3 -- docker ps`.", nil, retCodeInvalidArgument)
+	}
+
+	version := resolveUseVersion(args[0])
+	ensureVersionDownloaded(version)
+
+	dockerArgs := stripDoubleDashSeparator(args[1:])
+	if len(dockerArgs) > 0 && dockerArgs[0] == "docker" {
+		dockerArgs = dockerArgs[1:]
+	}
+
+	dockerPath := filepath.Join(getVersionDir(version), getBinaryName())
+	execProcess(dockerPath, dockerArgs, os.Environ())
+}
+
+// stripDoubleDashSeparator removes a leading "--" argument separator, if
+// present.
+func stripDoubleDashSeparator(args []string) []string {
+	if len(args) > 0 && args[0] == "--" {
+		return args[1:]
+	}
+	return args
+}
+
+// execProcess runs name with args and env, connected to the current
+// process's stdio, and dies if it can't be started or exits with an error.
+func execProcess(name string, args []string, env []string) {
+	command := exec.Command(name, args...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	command.Env = env
+
+	if err := command.Run(); err != nil {
+		die("Unable to run %s.", err, retCodeRuntimeError, name)
+	}
+}
+
 func current() {
 	current, err := getCurrentDockerVersion()
 	if err != nil {
@@ -216,35 +471,60 @@ func current() {
 }
 
 func list(pattern string) {
-	pattern += "*"
-	versions := getInstalledVersions(pattern)
+	versions := getInstalledVersions(pattern + "*")
 	current, _ := getCurrentDockerVersion()
 
 	for _, version := range versions {
-		if current == version {
-			color.Green("->\t%s", version)
+		display := describeInstalledVersion(version)
+		if current == display {
+			color.Green("->\t%s", display)
 		} else {
-			writeInfo("\t%s", version)
+			writeInfo("\t%s", display)
 		}
 	}
 }
 
-func install(version string) {
-	if version == "" {
-		version = getDockerVersionVar()
+// describeInstalledVersion renders an installed version the way `dvm
+// current`/`dvm list` report it, e.g. "experimental (1.13.0-rc1)".
+func describeInstalledVersion(version dockerversion.Version) string {
+	switch version.Raw {
+	case dockerversion.Experimental:
+		if resolved, err := getExperimentalDockerVersion(); err == nil {
+			return fmt.Sprintf("experimental (%s)", resolved)
+		}
+	case "system":
+		if resolved, err := getSystemDockerVersion(); err == nil {
+			return fmt.Sprintf("system (%s)", resolved)
+		}
 	}
 
-	if version == "" {
+	return version.String()
+}
+
+func install(versionArg string) {
+	if versionArg == "" {
+		versionArg = getDockerVersionVar()
+	}
+
+	if versionArg == "" {
 		die("The install command requires that a version is specified or the DOCKER_VERSION environment variable is set.", nil, retCodeInvalidArgument)
 	}
 
+	version := resolveInstallVersion(versionArg)
+	downloadVersion(version)
+	use(version.String())
+}
+
+// downloadVersion validates that version is a real, downloadable release
+// and downloads it, if it isn't already installed.
+func downloadVersion(version dockerversion.Version) {
 	if !versionExists(version) {
 		die("Version %s not found - try `dvm ls-remote` to browse available versions.", nil, retCodeInvalidOperation, version)
 	}
 
 	versionDir := getVersionDir(version)
 
-	if version == "experimental" && pathExists(versionDir) {
+	if version.IsExperimental() && pathExists(versionDir) {
 		// Always install latest of experimental build
 		err := os.RemoveAll(versionDir)
 		if err != nil {
@@ -254,38 +534,93 @@ func install(version string) {
 
 	if _, err := os.Stat(versionDir); err == nil {
 		writeWarning("%s is already installed", version)
-		use(version)
 		return
 	}
 
 	writeInfo("Installing %s...", version)
 
-	url := buildDownloadURL(version)
-	binaryPath := filepath.Join(getDvmDir(), "bin/docker", version, getBinaryName())
-	downloadFileWithChecksum(url, binaryPath)
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		die("Unable to create %s.", err, retCodeRuntimeError, versionDir)
+	}
+
+	downloadURL := buildDownloadURL(version)
+	opts := download.Options{
+		VerifyChecksum:  verifyChecksum,
+		VerifySignature: verifySignature,
+		PublicKey:       download.DockerReleasePublicKey,
+		Quiet:           silent,
+	}
+
+	binaryPath := filepath.Join(versionDir, getBinaryName())
+	if version.ShouldUseArchivedRelease() {
+		cacheDir := filepath.Join(dvmDir, ".cache")
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			die("Unable to create %s.", err, retCodeRuntimeError, cacheDir)
+		}
+
+		archivePath := filepath.Join(cacheDir, filepath.Base(downloadURL))
+		if err := download.File(downloadURL, archivePath, opts); err != nil {
+			die("Unable to download %s.", err, retCodeRuntimeError, downloadURL)
+		}
+		defer os.Remove(archivePath)
+
+		if err := extractFileFromArchive(archivePath, archiveBinaryPath(), binaryPath); err != nil {
+			die("Unable to extract %s from %s.", err, retCodeRuntimeError, archiveBinaryPath(), archivePath)
+		}
+	} else if err := download.File(downloadURL, binaryPath, opts); err != nil {
+		die("Unable to download %s.", err, retCodeRuntimeError, downloadURL)
+	}
 
 	writeDebug("Installed Docker %s to %s.", version, binaryPath)
-	use(version)
 }
 
-func buildDownloadURL(version string) string {
-	mirrorURL := "https://get.docker.com/builds"
+// ensureVersionDownloaded installs version if it is not already installed,
+// without activating it the way ensureVersionIsInstalled (used by `dvm
+// use`) does.
+func ensureVersionDownloaded(version dockerversion.Version) {
+	if isVersionInstalled(version) {
+		return
+	}
+
+	downloadVersion(version)
+}
+
+// buildDownloadURL builds the URL to download version from, rooted at
+// mirrorURL instead of Docker's own servers when it is set.
+func buildDownloadURL(version dockerversion.Version) string {
+	if version.ShouldUseArchivedRelease() {
+		base := mirrorURL
+		if base == "" {
+			base = "https://download.docker.com"
+		}
+		return fmt.Sprintf("%s/%s/static/stable/%s/docker-%s%s", base, dockerOS, dockerArch, version.Slug(), archiveFileExt())
+	}
+
+	// Legacy single-binary layout used by releases older than 1.11.
+	base := mirrorURL
+	versionSlug := version.Slug()
 
-	if version == "experimental" {
-		mirrorURL = "https://experimental.docker.com/builds"
-		version = "latest"
+	if version.IsExperimental() {
+		if base == "" {
+			base = "https://experimental.docker.com/builds"
+		}
+		versionSlug = "latest"
+	} else if base == "" {
+		base = "https://get.docker.com/builds"
 	}
 
-	return fmt.Sprintf("%s/%s/%s/docker-%s%s", mirrorURL, dockerOS, dockerArch, version, binaryFileExt)
+	return fmt.Sprintf("%s/%s/%s/docker-%s%s", base, dockerOS, dockerArch, versionSlug, binaryFileExt)
 }
 
-func uninstall(version string) {
-	if version == "" {
+func uninstall(versionArg string) {
+	if versionArg == "" {
 		die("The uninstall command requires that a version is specified.", nil, retCodeInvalidArgument)
 	}
 
+	version := dockerversion.New(versionArg)
+
 	current, _ := getCurrentDockerVersion()
-	if current == version {
+	if current == version.String() {
 		die("Cannot uninstall the currently active Docker version.", nil, retCodeInvalidOperation)
 	}
 
@@ -303,17 +638,17 @@ func uninstall(version string) {
 	writeInfo("Uninstalled Docker %s.", version)
 }
 
-func use(version string) {
-	if version == "" {
-		version = getDockerVersionVar()
+func use(versionArg string) {
+	if versionArg == "" {
+		versionArg = getDockerVersionVar()
 	}
 
-	if version == "" {
+	if versionArg == "" {
 		die("The use command requires that a version is specified or the DOCKER_VERSION environment variable is set.", nil, retCodeInvalidOperation)
 	}
 
 	// dvm use system undoes changes to the PATH and uses installed version of DOcker
-	if version == "system" {
+	if versionArg == "system" {
 		systemDockerVersion, err := getSystemDockerVersion()
 		if err != nil {
 			die("System version of Docker not found.", nil, retCodeInvalidOperation)
@@ -325,12 +660,7 @@ func use(version string) {
 		return
 	}
 
-	if aliasExists(version) {
-		alias := version
-		aliasedVersion, _ := ioutil.ReadFile(getAliasPath(alias))
-		version = string(aliasedVersion)
-		writeDebug("Using alias: %s -> %s", alias, version)
-	}
+	version := resolveAliasedUseVersion(versionArg)
 
 	ensureVersionIsInstalled(version)
 	removePreviousDvmVersionFromPath()
@@ -340,18 +670,51 @@ func use(version string) {
 	writeInfo("Now using Docker %s", version)
 }
 
-func which() {
+// resolveAliasedUseVersion resolves versionArg the same way `dvm use` does:
+// following it through an alias if it names one, then resolving the result
+// as an exact version, a semver range, or a symbolic selector via
+// resolveUseVersion.
+func resolveAliasedUseVersion(versionArg string) dockerversion.Version {
+	alias := ""
+	if aliasExists(versionArg) {
+		alias = versionArg
+		aliasedVersion, _ := ioutil.ReadFile(getAliasPath(alias))
+		versionArg = strings.TrimSpace(string(aliasedVersion))
+		writeDebug("Using alias: %s -> %s", alias, versionArg)
+	}
+
+	version := resolveUseVersion(versionArg)
+	if alias != "" {
+		version = dockerversion.NewFromAlias(alias, version.Raw)
+	}
+	return version
+}
+
+func which(useDvmrc bool) {
+	if useDvmrc {
+		versionArg, err := readDvmrc()
+		if err != nil {
+			die("Unable to read a version from .dvmrc.", err, retCodeInvalidOperation)
+		}
+
+		version := resolveAliasedUseVersion(versionArg)
+
+		writeInfo(filepath.Join(getVersionDir(version), getBinaryName()))
+		return
+	}
+
 	currentPath, err := getCurrentDockerPath()
 	if err == nil {
 		writeInfo(currentPath)
 	}
 }
 
-func alias(alias string, version string) {
-	if alias == "" || version == "" {
+func alias(alias string, versionArg string) {
+	if alias == "" || versionArg == "" {
 		die("The alias command requires both an alias name and a version.", nil, retCodeInvalidArgument)
 	}
 
+	version := dockerversion.New(versionArg)
 	if !isVersionInstalled(version) {
 		die("The aliased version, %s, is not installed.", nil, retCodeInvalidArgument, version)
 	}
@@ -361,7 +724,7 @@ func alias(alias string, version string) {
 		writeDebug("Overwriting existing alias.")
 	}
 
-	writeFile(aliasPath, version)
+	writeFile(aliasPath, version.String())
 	writeInfo("Aliased %s to %s.", alias, version)
 }
 
@@ -426,12 +789,13 @@ func getAliasPath(alias string) string {
 	return filepath.Join(dvmDir, "alias", alias)
 }
 
-func getDockerBinaryName(version string) string {
-	if version == "experimental" {
-		version = "latest"
+func getDockerBinaryName(version dockerversion.Version) string {
+	versionSlug := version.Slug()
+	if version.IsExperimental() {
+		versionSlug = "latest"
 	}
 
-	return fmt.Sprintf("docker-%s%s", version, binaryFileExt)
+	return fmt.Sprintf("docker-%s%s", versionSlug, binaryFileExt)
 }
 
 func getBinaryName() string {
@@ -443,7 +807,7 @@ func deactivate() {
 	writePathScript()
 }
 
-func prependDvmVersionToPath(version string) {
+func prependDvmVersionToPath(version dockerversion.Version) {
 	prependPath(getVersionDir(version))
 }
 
@@ -471,36 +835,253 @@ func removePreviousDvmVersionFromPath() {
 	removePath(getCleanDvmPathRegex())
 }
 
-func ensureVersionIsInstalled(version string) {
+func ensureVersionIsInstalled(version dockerversion.Version) {
 	if isVersionInstalled(version) {
 		return
 	}
 
 	writeInfo("%s is not installed. Installing now...", version)
-	install(version)
+	install(version.String())
 }
 
-func isVersionInstalled(version string) bool {
-	installedVersions := getInstalledVersions(version)
+func isVersionInstalled(version dockerversion.Version) bool {
+	installedVersions := getInstalledVersions(version.Slug())
 
 	return len(installedVersions) > 0
 }
 
-func versionExists(version string) bool {
-	if version == "experimental" {
+func versionExists(version dockerversion.Version) bool {
+	if version.IsExperimental() {
 		return true
 	}
 
-	availableVersions := getAvailableVersions(version)
+	availableVersions := getAvailableVersions(version.String())
 
 	for _, availableVersion := range availableVersions {
-		if version == availableVersion {
+		if version.String() == availableVersion.String() {
 			return true
 		}
 	}
 	return false
 }
 
+// errNotAVersionSpec is returned by resolveVersion when spec is neither a
+// symbolic selector nor a semver range, signalling that the caller should
+// treat it as an exact version or alias instead.
+var errNotAVersionSpec = errors.New("not a version specifier")
+
+// resolveInstallVersion resolves versionArg, which may be an exact version,
+// a semver range, or a symbolic selector, against the versions available to
+// download. Exact versions and aliases are returned unchanged.
+func resolveInstallVersion(versionArg string) dockerversion.Version {
+	version, err := resolveVersion(versionArg, getAvailableVersions(".*"), includePrereleases)
+	if err == nil {
+		return version
+	}
+	if err != errNotAVersionSpec {
+		die("Unable to resolve version %s.", err, retCodeInvalidOperation, versionArg)
+	}
+
+	return dockerversion.New(versionArg)
+}
+
+// resolveUseVersion is like resolveInstallVersion, but prefers resolving a
+// range or symbolic selector against the versions already installed, only
+// falling back to the versions available to download when nothing
+// installed satisfies it. This lets `dvm use`/`dvm exec`/`dvm run` select
+// and then install a release that isn't on this machine yet, e.g. the first
+// time a teammate runs `dvm use` against a shared `.dvmrc`.
+func resolveUseVersion(versionArg string) dockerversion.Version {
+	version, err := resolveVersion(versionArg, getInstalledVersions("*"), includePrereleases)
+	if err == nil {
+		return version
+	}
+	if err == errNotAVersionSpec {
+		return dockerversion.New(versionArg)
+	}
+
+	version, err = resolveVersion(versionArg, getAvailableVersions(".*"), includePrereleases)
+	if err != nil {
+		die("Unable to resolve version %s.", err, retCodeInvalidOperation, versionArg)
+	}
+
+	return version
+}
+
+// resolveVersion resolves spec, a symbolic selector (latest, latest-stable,
+// latest-lts, experimental) or a semver range (e.g. "^1.12.0", "~1.12.0",
+// "1.12.x", ">=1.10.0 <1.13.0"), to the highest matching Version in
+// candidates. It prefers non-prerelease matches unless includePrereleases is
+// set. It returns errNotAVersionSpec, unwrapped, when spec is an exact
+// version rather than a selector or range, so the caller can fall back to
+// treating it as an exact version or alias, even if that exact version isn't
+// among candidates yet.
+func resolveVersion(spec string, candidates []dockerversion.Version, includePrereleases bool) (dockerversion.Version, error) {
+	switch spec {
+	case dockerversion.Experimental:
+		return dockerversion.New(dockerversion.Experimental), nil
+	case "latest", "latest-stable", "latest-lts":
+		// Docker does not publish a separate LTS channel, so latest-lts
+		// resolves the same as latest-stable.
+		return highestMatch(spec, candidates, includePrereleases, dockerversion.Version.IsSemVer)
+	}
+
+	// An exact version (including one with a prerelease tag, e.g.
+	// "1.12.0-rc1") is itself a valid, single-version semver range, but it
+	// isn't a specifier that needs resolving: let the caller fall back to
+	// treating it literally, the same way it always has, whether or not it
+	// happens to already be among candidates.
+	if _, err := semver.Parse(strings.TrimPrefix(spec, "v")); err == nil {
+		return dockerversion.Version{}, errNotAVersionSpec
+	}
+
+	expandedSpec, err := expandCaretTilde(spec)
+	if err != nil {
+		return dockerversion.Version{}, errNotAVersionSpec
+	}
+
+	versionRange, err := semver.ParseRange(expandedSpec)
+	if err != nil {
+		return dockerversion.Version{}, errNotAVersionSpec
+	}
+
+	return highestMatch(spec, candidates, includePrereleases, func(v dockerversion.Version) bool {
+		return v.IsSemVer() && versionRange(v.SemVer)
+	})
+}
+
+// expandCaretTilde rewrites the npm-style "^" and "~" comparators in spec
+// into the ">=" / "<" comparator pairs that blang/semver's ParseRange
+// understands natively, leaving every other token untouched. spec may
+// combine multiple ranges with "||" (OR) and/or spaces (AND).
+func expandCaretTilde(spec string) (string, error) {
+	orRanges := strings.Split(spec, "||")
+	for i, orRange := range orRanges {
+		tokens := strings.Fields(orRange)
+		for j, token := range tokens {
+			switch {
+			case strings.HasPrefix(token, "^"):
+				expanded, err := expandCaret(token[1:])
+				if err != nil {
+					return "", err
+				}
+				tokens[j] = expanded
+			case strings.HasPrefix(token, "~"):
+				expanded, err := expandTilde(token[1:])
+				if err != nil {
+					return "", err
+				}
+				tokens[j] = expanded
+			}
+		}
+		orRanges[i] = strings.Join(tokens, " ")
+	}
+	return strings.Join(orRanges, " || "), nil
+}
+
+// expandCaret translates an npm-style caret version, e.g. "1.12" or "1.12.0",
+// into the range ">=1.12.0 <2.0.0" that allows any change that does not
+// modify the left-most non-zero component.
+func expandCaret(version string) (string, error) {
+	v, err := parsePartialVersion(version)
+	if err != nil {
+		return "", err
+	}
+
+	var upper semver.Version
+	switch {
+	case v.Major > 0:
+		upper = semver.Version{Major: v.Major + 1}
+	case v.Minor > 0:
+		upper = semver.Version{Minor: v.Minor + 1}
+	default:
+		upper = semver.Version{Patch: v.Patch + 1}
+	}
+
+	return fmt.Sprintf(">=%s <%s", v.String(), upper.String()), nil
+}
+
+// expandTilde translates an npm-style tilde version, e.g. "1.12" or
+// "1.12.0", into the range ">=1.12.0 <1.13.0" that allows patch-level
+// changes only.
+func expandTilde(version string) (string, error) {
+	v, err := parsePartialVersion(version)
+	if err != nil {
+		return "", err
+	}
+
+	upper := semver.Version{Major: v.Major, Minor: v.Minor + 1}
+	return fmt.Sprintf(">=%s <%s", v.String(), upper.String()), nil
+}
+
+// parsePartialVersion parses version as a semantic version, padding any
+// missing minor or patch component with 0, so that caret/tilde specs such as
+// "^1.12" or "~1" resolve the same way their full "1.12.0"/"1.0.0" forms do.
+func parsePartialVersion(version string) (semver.Version, error) {
+	components := strings.SplitN(version, ".", 3)
+	for len(components) < 3 {
+		components = append(components, "0")
+	}
+	return semver.Parse(strings.Join(components, "."))
+}
+
+// highestMatch returns the highest Version in candidates for which match
+// returns true, preferring a non-prerelease match over a prerelease one
+// unless includePrereleases is set.
+func highestMatch(spec string, candidates []dockerversion.Version, includePrereleases bool, match func(dockerversion.Version) bool) (dockerversion.Version, error) {
+	var best, bestPrerelease dockerversion.Version
+	var found, foundPrerelease bool
+
+	for _, candidate := range candidates {
+		if !match(candidate) {
+			continue
+		}
+
+		if candidate.IsPrerelease() {
+			if !foundPrerelease || candidate.Compare(bestPrerelease) > 0 {
+				bestPrerelease, foundPrerelease = candidate, true
+			}
+			continue
+		}
+
+		if !found || candidate.Compare(best) > 0 {
+			best, found = candidate, true
+		}
+	}
+
+	if includePrereleases && foundPrerelease && (!found || bestPrerelease.Compare(best) > 0) {
+		return bestPrerelease, nil
+	}
+	if found {
+		return best, nil
+	}
+
+	return dockerversion.Version{}, fmt.Errorf("no version matching %q was found; closest candidates: %s", spec, describeClosestCandidates(candidates))
+}
+
+// describeClosestCandidates renders up to 5 of the highest candidates, for
+// use in "no match found" error messages.
+func describeClosestCandidates(candidates []dockerversion.Version) string {
+	if len(candidates) == 0 {
+		return "none available"
+	}
+
+	sorted := make([]dockerversion.Version, len(candidates))
+	copy(sorted, candidates)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Compare(sorted[j]) > 0 })
+
+	limit := 5
+	if len(sorted) < limit {
+		limit = len(sorted)
+	}
+
+	names := make([]string, limit)
+	for i := 0; i < limit; i++ {
+		names[i] = sorted[i].String()
+	}
+	return strings.Join(names, ", ")
+}
+
 func getCurrentDockerPath() (string, error) {
 	currentDockerPath, err := exec.LookPath("docker")
 	return currentDockerPath, err
@@ -543,7 +1124,7 @@ func getSystemDockerVersion() (string, error) {
 }
 
 func getExperimentalDockerPath() (string, error) {
-	experimentalVersionPath := filepath.Join(getVersionDir("experimental"), getBinaryName())
+	experimentalVersionPath := filepath.Join(getVersionDir(dockerversion.New(dockerversion.Experimental)), getBinaryName())
 	_, err := os.Stat(experimentalVersionPath)
 	return experimentalVersionPath, err
 }
@@ -573,70 +1154,88 @@ func getDockerVersion(dockerPath string) (string, error) {
 func listRemote(pattern string) {
 	versions := getAvailableVersions(pattern)
 	for _, version := range versions {
-		writeInfo(version)
+		writeInfo(version.String())
 	}
 }
 
-func getInstalledVersions(pattern string) []string {
-	versions, _ := filepath.Glob(getVersionDir(pattern))
+func getInstalledVersions(pattern string) []dockerversion.Version {
+	versionDirs, _ := filepath.Glob(getVersionDir(dockerversion.New(pattern)))
 
-	var results []string
-	for _, versionDir := range versions {
-		version := filepath.Base(versionDir)
+	var results []dockerversion.Version
+	for _, versionDir := range versionDirs {
+		version := dockerversion.New(filepath.Base(versionDir))
 
-		if version == "experimental" {
-			experimentalVersion, err := getExperimentalDockerVersion()
-			if err != nil {
-				writeDebug("Unable to get version of installed experimental version at %s.\n%s", getVersionDir("experimental"), err)
+		if version.IsExperimental() {
+			if _, err := getExperimentalDockerVersion(); err != nil {
+				writeDebug("Unable to get version of installed experimental version at %s.\n%s", versionDir, err)
 				continue
 			}
-			version = fmt.Sprintf("experimental (%s)", experimentalVersion)
 		}
 
 		results = append(results, version)
 	}
 
 	if glob.Glob(pattern, "system") {
-		systemVersion, err := getSystemDockerVersion()
-		if err == nil {
-			results = append(results, fmt.Sprintf("system (%s)", systemVersion))
+		if _, err := getSystemDockerVersion(); err == nil {
+			results = append(results, dockerversion.New("system"))
 		}
 	}
 
-	sort.Strings(results)
+	sort.Slice(results, func(i, j int) bool { return results[i].String() < results[j].String() })
 	return results
 }
 
-func getAvailableVersions(pattern string) []string {
-	gh := buildGithubClient()
-	tags, response, err := gh.Repositories.ListTags("docker", "docker", nil)
+func getAvailableVersions(pattern string) []dockerversion.Version {
+	source := buildVersionSource(refreshVersionCache)
+	versions, err := source.ListVersions()
 	if err != nil {
-		warnWhenRateLimitExceeded(err, response)
-		die("Unable to retrieve list of Docker tags from GitHub", err, retCodeRuntimeError)
-	}
-	if response.StatusCode != 200 {
-		die("Unable to retrieve list of Docker tags from GitHub (Status %s).", nil, retCodeRuntimeError, response.StatusCode)
+		die("Unable to retrieve list of available Docker versions.", err, retCodeRuntimeError)
 	}
 
-	versionRegex := regexp.MustCompile(`^v([1-9]+\.\d+\.\d+)$`)
 	patternRegex, err := regexp.Compile(pattern)
 	if err != nil {
 		die("Invalid pattern.", err, retCodeInvalidOperation)
 	}
 
-	var results []string
-	for _, tag := range tags {
-		version := *tag.Name
-		match := versionRegex.FindStringSubmatch(version)
-		if len(match) > 1 && patternRegex.MatchString(version) {
-			results = append(results, match[1])
+	var results []dockerversion.Version
+	for _, version := range versions {
+		if patternRegex.MatchString(version.String()) {
+			results = append(results, version)
 		}
 	}
 
-	sort.Strings(results)
+	sort.Slice(results, func(i, j int) bool { return results[i].Compare(results[j]) < 0 })
 	return results
 }
 
+// versionCacheTTL is how long the cached list of available Docker versions
+// is reused before dvm queries the upstream sources again.
+const versionCacheTTL = 24 * time.Hour
+
+// buildVersionSource merges the Docker release index for the current OS
+// and architecture with the moby/moby GitHub tag history, since the former
+// only covers releases from the 1.11 archive format cutover onward, and
+// caches the merged result to disk. The download index is rooted at
+// mirrorURL instead of Docker's own servers when it is set, the same way
+// buildDownloadURL roots the binary download there; the GitHub tag history
+// has no mirror equivalent, so it is skipped entirely when mirrorURL is
+// set, rather than having an air-gapped install still reach out to
+// api.github.com.
+func buildVersionSource(refresh bool) versionsource.VersionSource {
+	downloadIndex := versionsource.NewDockerDownloadSource(dockerOS, dockerArch, mirrorURL)
+	if mirrorURL != "" {
+		return versionsource.NewCachedSource(downloadIndex, getVersionCachePath(), versionCacheTTL, refresh)
+	}
+
+	githubTags := versionsource.NewGitHubTagSource(buildGithubClient())
+	combined := versionsource.NewMergedSource(downloadIndex, githubTags)
+	return versionsource.NewCachedSource(combined, getVersionCachePath(), versionCacheTTL, refresh)
+}
+
+func getVersionCachePath() string {
+	return filepath.Join(dvmDir, ".cache", fmt.Sprintf("versions-%s-%s.json", dockerOS, dockerArch))
+}
+
 func isUpgradeAvailable() (bool, string) {
 	gh := buildGithubClient()
 	release, response, err := gh.Repositories.GetLatestRelease("getcarina", "dvm")
@@ -667,8 +1266,8 @@ func isUpgradeAvailable() (bool, string) {
 	return latestVersion.Compare(currentVersion) > 0, *release.TagName
 }
 
-func getVersionDir(version string) string {
-	return filepath.Join(dvmDir, "bin", "docker", version)
+func getVersionDir(version dockerversion.Version) string {
+	return filepath.Join(dvmDir, "bin", "docker", version.Slug())
 }
 
 func getDockerVersionVar() string {
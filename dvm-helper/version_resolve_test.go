@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/getcarina/dvm/dvm-helper/dockerversion"
+)
+
+func versions(raw ...string) []dockerversion.Version {
+	result := make([]dockerversion.Version, len(raw))
+	for i, r := range raw {
+		result[i] = dockerversion.New(r)
+	}
+	return result
+}
+
+func TestResolveVersion_Caret(t *testing.T) {
+	candidates := versions("1.11.0", "1.12.0", "1.12.3", "1.13.0")
+
+	actual, err := resolveVersion("^1.12", candidates, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.String() != "1.13.0" {
+		t.Errorf("expected 1.13.0, got %s", actual.String())
+	}
+}
+
+func TestResolveVersion_Tilde(t *testing.T) {
+	candidates := versions("1.11.0", "1.12.0", "1.12.3", "1.13.0")
+
+	actual, err := resolveVersion("~1.12.0", candidates, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.String() != "1.12.3" {
+		t.Errorf("expected 1.12.3, got %s", actual.String())
+	}
+}
+
+func TestResolveVersion_ExactVersionNotAmongCandidatesFallsBack(t *testing.T) {
+	candidates := versions("1.11.0", "1.12.0")
+
+	_, err := resolveVersion("1.12.3", candidates, false)
+	if err != errNotAVersionSpec {
+		t.Errorf("expected errNotAVersionSpec so the caller falls back to dockerversion.New, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package versionsource
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/getcarina/dvm/dvm-helper/dockerversion"
+)
+
+// CachedSource wraps another VersionSource, persisting its results to
+// CachePath and reusing them until they are older than TTL.
+type CachedSource struct {
+	Source    VersionSource
+	CachePath string
+	TTL       time.Duration
+	// Refresh bypasses the cache and always queries Source, updating the
+	// cache with the fresh results.
+	Refresh bool
+}
+
+// NewCachedSource wraps source with an on-disk cache at cachePath.
+func NewCachedSource(source VersionSource, cachePath string, ttl time.Duration, refresh bool) *CachedSource {
+	return &CachedSource{Source: source, CachePath: cachePath, TTL: ttl, Refresh: refresh}
+}
+
+type cacheFile struct {
+	FetchedAt time.Time
+	Versions  []string
+}
+
+// ListVersions returns the cached versions when they exist and are within
+// TTL, otherwise it queries Source and refreshes the cache.
+func (s *CachedSource) ListVersions() ([]dockerversion.Version, error) {
+	if !s.Refresh {
+		if versions, ok := s.readCache(); ok {
+			return versions, nil
+		}
+	}
+
+	versions, err := s.Source.ListVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	s.writeCache(versions)
+	return versions, nil
+}
+
+func (s *CachedSource) readCache() ([]dockerversion.Version, bool) {
+	contents, err := ioutil.ReadFile(s.CachePath)
+	if err != nil {
+		return nil, false
+	}
+
+	var cache cacheFile
+	if err := json.Unmarshal(contents, &cache); err != nil {
+		return nil, false
+	}
+
+	if time.Since(cache.FetchedAt) > s.TTL {
+		return nil, false
+	}
+
+	versions := make([]dockerversion.Version, len(cache.Versions))
+	for i, raw := range cache.Versions {
+		versions[i] = dockerversion.New(raw)
+	}
+	return versions, true
+}
+
+func (s *CachedSource) writeCache(versions []dockerversion.Version) {
+	raw := make([]string, len(versions))
+	for i, version := range versions {
+		raw[i] = version.String()
+	}
+
+	contents, err := json.Marshal(cacheFile{FetchedAt: time.Now(), Versions: raw})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.CachePath), 0755); err != nil {
+		return
+	}
+
+	ioutil.WriteFile(s.CachePath, contents, 0644)
+}
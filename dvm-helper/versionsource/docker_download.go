@@ -0,0 +1,72 @@
+package versionsource
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+
+	"github.com/getcarina/dvm/dvm-helper/dockerversion"
+)
+
+// defaultDockerDownloadBaseURL is the root of Docker's own static release
+// index, used when BaseURL is not set to an internal mirror.
+const defaultDockerDownloadBaseURL = "https://download.docker.com"
+
+// DockerDownloadSource lists Docker releases by scraping the static binary
+// index page Docker (or a mirror of it) publishes for a given OS/arch, e.g.
+// https://download.docker.com/linux/static/stable/x86_64/.
+type DockerDownloadSource struct {
+	OS   string
+	Arch string
+	// BaseURL roots the index page at an internal mirror instead of
+	// Docker's own servers, e.g. for an air-gapped environment. Defaults to
+	// defaultDockerDownloadBaseURL when empty.
+	BaseURL string
+}
+
+// NewDockerDownloadSource creates a DockerDownloadSource for the given OS,
+// architecture and mirror base URL (pass "" to use Docker's own servers),
+// using the same values dvm uses to build download URLs.
+func NewDockerDownloadSource(os string, arch string, baseURL string) *DockerDownloadSource {
+	return &DockerDownloadSource{OS: os, Arch: arch, BaseURL: baseURL}
+}
+
+var archivedVersionHrefRegex = regexp.MustCompile(`href="docker-([0-9]+\.[0-9]+\.[0-9]+(?:-[0-9A-Za-z.]+)?)\.(?:tgz|zip)"`)
+
+// ListVersions fetches and parses the index page, returning one Version per
+// docker-<version>.tgz/.zip href it finds.
+func (s *DockerDownloadSource) ListVersions() ([]dockerversion.Version, error) {
+	base := s.BaseURL
+	if base == "" {
+		base = defaultDockerDownloadBaseURL
+	}
+	indexURL := fmt.Sprintf("%s/%s/static/stable/%s/", base, s.OS, s.Arch)
+
+	response, err := http.Get(indexURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to retrieve %s (status %d)", indexURL, response.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := archivedVersionHrefRegex.FindAllStringSubmatch(string(body), -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no Docker releases found at %s", indexURL)
+	}
+
+	versions := make([]dockerversion.Version, len(matches))
+	for i, match := range matches {
+		versions[i] = dockerversion.New(match[1])
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,55 @@
+package versionsource
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/getcarina/dvm/dvm-helper/dockerversion"
+	"github.com/google/go-github/github"
+)
+
+// GitHubTagSource lists Docker releases from the moby/moby tag history,
+// paginating through every page of results rather than just the first. dvm
+// merges it with the download.docker.com index, which does not list releases
+// older than 1.11, so it also still lists versions when that index cannot be
+// reached.
+type GitHubTagSource struct {
+	Client *github.Client
+}
+
+// NewGitHubTagSource creates a GitHubTagSource using the given client.
+func NewGitHubTagSource(client *github.Client) *GitHubTagSource {
+	return &GitHubTagSource{Client: client}
+}
+
+var githubTagVersionRegex = regexp.MustCompile(`^v([1-9]\d*\.\d+\.\d+(?:-[0-9A-Za-z.]+)?)$`)
+
+// ListVersions pages through every tag on moby/moby (the renamed
+// docker/docker), returning one Version per semver-looking tag.
+func (s *GitHubTagSource) ListVersions() ([]dockerversion.Version, error) {
+	var versions []dockerversion.Version
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		tags, response, err := s.Client.Repositories.ListTags("moby", "moby", opts)
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode != 200 {
+			return nil, fmt.Errorf("unable to retrieve moby/moby tags (status %d)", response.StatusCode)
+		}
+
+		for _, tag := range tags {
+			if match := githubTagVersionRegex.FindStringSubmatch(*tag.Name); match != nil {
+				versions = append(versions, dockerversion.New(match[1]))
+			}
+		}
+
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+
+	return versions, nil
+}
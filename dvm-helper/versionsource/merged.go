@@ -0,0 +1,45 @@
+package versionsource
+
+import "github.com/getcarina/dvm/dvm-helper/dockerversion"
+
+// MergedSource unions the versions reported by Primary and Secondary, since
+// their coverage differs (e.g. the Docker download index only lists releases
+// from the 1.11 archive format cutover onward, while the GitHub tag history
+// goes back further). Versions reported by both are listed once. It only
+// gives up entirely if both sources fail.
+type MergedSource struct {
+	Primary   VersionSource
+	Secondary VersionSource
+}
+
+// NewMergedSource creates a MergedSource that unions primary's and
+// secondary's versions.
+func NewMergedSource(primary VersionSource, secondary VersionSource) *MergedSource {
+	return &MergedSource{Primary: primary, Secondary: secondary}
+}
+
+// ListVersions returns the union of Primary's and Secondary's versions,
+// deduplicated by version string. Either source may fail without failing
+// the other's contribution; ListVersions only errors if both do.
+func (s *MergedSource) ListVersions() ([]dockerversion.Version, error) {
+	primaryVersions, primaryErr := s.Primary.ListVersions()
+	secondaryVersions, secondaryErr := s.Secondary.ListVersions()
+	if primaryErr != nil && secondaryErr != nil {
+		return nil, primaryErr
+	}
+
+	seen := make(map[string]bool, len(primaryVersions))
+	versions := make([]dockerversion.Version, 0, len(primaryVersions)+len(secondaryVersions))
+	for _, version := range primaryVersions {
+		seen[version.String()] = true
+		versions = append(versions, version)
+	}
+	for _, version := range secondaryVersions {
+		if !seen[version.String()] {
+			seen[version.String()] = true
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,76 @@
+package versionsource
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/getcarina/dvm/dvm-helper/dockerversion"
+)
+
+type stubSource struct {
+	versions []dockerversion.Version
+	err      error
+}
+
+func (s stubSource) ListVersions() ([]dockerversion.Version, error) {
+	return s.versions, s.err
+}
+
+func versionStrings(versions []dockerversion.Version) []string {
+	result := make([]string, len(versions))
+	for i, v := range versions {
+		result[i] = v.String()
+	}
+	return result
+}
+
+func TestMergedSource_UnionsAndDeduplicatesSources(t *testing.T) {
+	primary := stubSource{versions: []dockerversion.Version{dockerversion.New("1.12.0"), dockerversion.New("1.12.3")}}
+	secondary := stubSource{versions: []dockerversion.Version{dockerversion.New("1.9.1"), dockerversion.New("1.12.0")}}
+
+	merged, err := NewMergedSource(primary, secondary).ListVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"1.12.0", "1.12.3", "1.9.1"}
+	actual := versionStrings(merged)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for _, version := range expected {
+		found := false
+		for _, a := range actual {
+			if a == version {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected %v to contain %s", actual, version)
+		}
+	}
+}
+
+func TestMergedSource_OneSourceFailing(t *testing.T) {
+	primary := stubSource{err: errors.New("download.docker.com unreachable")}
+	secondary := stubSource{versions: []dockerversion.Version{dockerversion.New("1.9.1")}}
+
+	merged, err := NewMergedSource(primary, secondary).ListVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 1 || merged[0].String() != "1.9.1" {
+		t.Errorf("expected [1.9.1], got %v", versionStrings(merged))
+	}
+}
+
+func TestMergedSource_BothSourcesFailing(t *testing.T) {
+	primaryErr := errors.New("download.docker.com unreachable")
+	primary := stubSource{err: primaryErr}
+	secondary := stubSource{err: errors.New("github unreachable")}
+
+	_, err := NewMergedSource(primary, secondary).ListVersions()
+	if err != primaryErr {
+		t.Errorf("expected primary's error, got %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package versionsource lists the Docker versions available to install,
+// pulling from whichever upstream source dvm is configured to use.
+package versionsource
+
+import "github.com/getcarina/dvm/dvm-helper/dockerversion"
+
+// VersionSource lists the Docker versions available to install.
+type VersionSource interface {
+	ListVersions() ([]dockerversion.Version, error)
+}